@@ -39,8 +39,14 @@ func (ake *AKE) calcDHSharedSecret() *big.Int {
 	return modExp(ake.theirPublicValue, ake.secretExponent)
 }
 
+// policies returns the Policies governing this AKE, the same way rand()
+// exposes the Conversation's randomness source to AKE code.
+func (ake *AKE) policies() Policies {
+	return ake.akeContext.policies
+}
+
 func (ake *AKE) generateEncryptedSignature(key *akeKeys) ([]byte, error) {
-	verifyData := appendAll(ake.ourPublicValue, ake.theirPublicValue, &ake.ourKey.PublicKey, ake.ourKeyID)
+	verifyData := appendAll(ake.ourPublicValue, ake.theirPublicValue, ake.ourKey, ake.ourKeyID)
 
 	mb := sumHMAC(key.m1[:], verifyData)
 	xb, err := ake.calcXb(key, mb)
@@ -51,15 +57,15 @@ func (ake *AKE) generateEncryptedSignature(key *akeKeys) ([]byte, error) {
 
 	return appendData(nil, xb), nil
 }
-func appendAll(one, two *big.Int, publicKey *PublicKey, keyID uint32) []byte {
-	return appendWord(append(appendMPI(appendMPI(nil, one), two), publicKey.serialize()...), keyID)
+func appendAll(one, two *big.Int, longTermKey LongTermKey, keyID uint32) []byte {
+	return appendWord(append(appendMPI(appendMPI(nil, one), two), longTermKey.Serialize()...), keyID)
 }
 
 func (ake *AKE) calcXb(key *akeKeys, mb []byte) ([]byte, error) {
-	xb := ake.ourKey.PublicKey.serialize()
+	xb := ake.ourKey.Serialize()
 	xb = appendWord(xb, ake.ourKeyID)
 
-	sigb, err := ake.ourKey.sign(ake.rand(), mb)
+	sigb, err := ake.ourKey.Sign(ake.rand(), mb)
 	if err != nil {
 		if err == io.ErrUnexpectedEOF {
 			return nil, errShortRandomRead
@@ -284,9 +290,7 @@ func (ake *AKE) processEncryptedSig(encryptedSig []byte, theirMAC []byte, keys *
 		return err
 	}
 
-	ake.theirKey = &PublicKey{}
-
-	nextPoint, ok1 := ake.theirKey.parse(decryptedSig)
+	theirKey, nextPoint, ok1 := parseLongTermKey(decryptedSig)
 
 	_, keyID, ok2 := extractWord(nextPoint)
 
@@ -294,13 +298,18 @@ func (ake *AKE) processEncryptedSig(encryptedSig []byte, theirMAC []byte, keys *
 		return errCorruptEncryptedSignature
 	}
 
+	if !acceptableLongTermKeyType(theirKey.Type(), ake.policies()) {
+		return errors.New("otr: peer's long-term key type is not allowed by policy")
+	}
+	ake.theirKey = theirKey
+
 	sig := nextPoint[4:]
 
 	verifyData := appendAll(ake.theirPublicValue, ake.ourPublicValue, ake.theirKey, keyID)
 
 	mb := sumHMAC(keys.m1[:], verifyData)
 
-	rest, ok := ake.theirKey.verify(mb, sig)
+	rest, ok := ake.theirKey.Verify(mb, sig)
 	if !ok {
 		return errors.New("bad signature in encrypted signature")
 	}
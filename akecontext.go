@@ -0,0 +1,28 @@
+package otr3
+
+import "math/big"
+
+// akeContext holds the running state of an in-progress authenticated key
+// exchange: the DH exponents being negotiated, the long-term keys used to
+// sign and verify the exchange, and the symmetric keys derived once it
+// completes. AKE embeds it directly, the same way Conversation embeds the
+// other per-conversation state machines.
+type akeContext struct {
+	policies Policies
+
+	ourKeyID   uint32
+	theirKeyID uint32
+
+	ourKey   LongTermKey
+	theirKey LongTermKey
+
+	secretExponent   *big.Int
+	ourPublicValue   *big.Int
+	theirPublicValue *big.Int
+
+	r           [16]byte
+	encryptedGx []byte
+	hashedGx    [32]byte
+
+	sigKey akeKeys
+}
@@ -0,0 +1,74 @@
+package otr3
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"io"
+)
+
+// keyTypeEd25519 is the wire tag otr3 uses to identify an Ed25519
+// LongTermKey, following the same type-then-key-bytes layout OTRv3 already
+// uses for DSA (keyTypeDSA). It isn't part of the upstream OTR spec, so
+// both sides of an AKE must opt in to it before it is attempted, or a
+// stock OTRv3 peer will simply fail to parse the signature message.
+const keyTypeEd25519 = uint16(3)
+
+// Ed25519Key is a LongTermKey backed by an Ed25519 keypair. Private may be
+// nil for a key that is only used to verify a peer's signature.
+type Ed25519Key struct {
+	Public  ed25519.PublicKey
+	Private ed25519.PrivateKey
+}
+
+// GenerateEd25519Key creates a new Ed25519Key, reading randomness from
+// rand.
+func GenerateEd25519Key(rand io.Reader) (*Ed25519Key, error) {
+	pub, priv, err := ed25519.GenerateKey(rand)
+	if err != nil {
+		return nil, err
+	}
+	return &Ed25519Key{Public: pub, Private: priv}, nil
+}
+
+// Serialize implements LongTermKey.
+func (k *Ed25519Key) Serialize() []byte {
+	return append(appendShort(nil, keyTypeEd25519), []byte(k.Public)...)
+}
+
+// Sign implements LongTermKey. Ed25519 signing is deterministic, so rand is
+// unused, but it is still taken so Ed25519Key satisfies the same interface
+// as PrivateKey.
+func (k *Ed25519Key) Sign(rand io.Reader, digest []byte) ([]byte, error) {
+	if k.Private == nil {
+		return nil, errors.New("otr: no Ed25519 private key available to sign with")
+	}
+	return ed25519.Sign(k.Private, digest), nil
+}
+
+// Verify implements LongTermKey.
+func (k *Ed25519Key) Verify(digest, sig []byte) (rest []byte, ok bool) {
+	if len(sig) < ed25519.SignatureSize {
+		return sig, false
+	}
+	ok = ed25519.Verify(k.Public, digest, sig[:ed25519.SignatureSize])
+	return sig[ed25519.SignatureSize:], ok
+}
+
+// Parse implements LongTermKey.
+func (k *Ed25519Key) Parse(data []byte) (rest []byte, ok bool) {
+	var tag uint16
+	data, tag, ok = extractShort(data)
+	if !ok || tag != keyTypeEd25519 {
+		return data, false
+	}
+	if len(data) < ed25519.PublicKeySize {
+		return data, false
+	}
+	k.Public = append(ed25519.PublicKey{}, data[:ed25519.PublicKeySize]...)
+	return data[ed25519.PublicKeySize:], true
+}
+
+// Type implements LongTermKey.
+func (k *Ed25519Key) Type() uint16 {
+	return keyTypeEd25519
+}
@@ -0,0 +1,108 @@
+package otr3
+
+import (
+	"crypto/sha256"
+	"errors"
+)
+
+// tlvTypeExtraSymmetricKey is the TLV type OTRv3 dedicates to signalling
+// use of the Extra Symmetric Key, for example to key a file transfer or a
+// voice session that runs over an out-of-band channel.
+const tlvTypeExtraSymmetricKey = uint16(8)
+
+// ExtraSymmetricKeyEventHandler is notified when the peer signals use of
+// the Extra Symmetric Key for an out-of-band channel, via a TLV type 8
+// inside a data message.
+type ExtraSymmetricKeyEventHandler interface {
+	// HandleExtraSymmetricKeyEvent is called with the usage and
+	// usageData the peer supplied, together with the derived key.
+	HandleExtraSymmetricKeyEvent(usage uint32, usageData, key []byte)
+}
+
+// SetExtraSymmetricKeyEventHandler installs the handler notified when the
+// peer signals use of the Extra Symmetric Key. Passing nil disables the
+// notification, and the request is still parsed but silently dropped.
+func (c *Conversation) SetExtraSymmetricKeyEventHandler(h ExtraSymmetricKeyEventHandler) {
+	c.extraSymmetricKeyEventHandler = h
+}
+
+// ExtraSymmetricKey returns the current session's 256-bit Extra Symmetric
+// Key, as defined by the OTRv3 spec: SHA256(0xFF || sendingAESKey). It is
+// derived from this side's own sending AES key, matching what the peer
+// will derive as their receiving AES key, so it must be re-derived after
+// every rekey rather than cached across a NewKeys SecurityChange.
+func (c *Conversation) ExtraSymmetricKey() ([]byte, error) {
+	if c.msgState != encrypted {
+		return nil, errors.New("otr: cannot compute the extra symmetric key outside of an encrypted conversation")
+	}
+
+	return extraSymmetricKeyFrom(c.keys.sendingAESKey[:]), nil
+}
+
+// extraSymmetricKeyFrom derives the Extra Symmetric Key from one side of
+// the ratchet's current AES key, as SHA256(0xFF || aesKey).
+func extraSymmetricKeyFrom(aesKey []byte) []byte {
+	key := sha256.Sum256(append([]byte{0xff}, aesKey...))
+	return key[:]
+}
+
+// SendExtraSymmetricKeyRequest packages a TLV type 8 inside a data message,
+// the same way createSerializedDataMessage already piggybacks other TLVs,
+// telling the peer which usage the Extra Symmetric Key is being derived
+// for. usageData is opaque to otr3; its format is agreed on out of band by
+// the two applications.
+func (c *Conversation) SendExtraSymmetricKeyRequest(usage uint32, usageData []byte) ([]ValidMessage, error) {
+	if c.msgState != encrypted {
+		return nil, errors.New("otr: cannot send an extra symmetric key request outside of an encrypted conversation")
+	}
+
+	value := appendWord(nil, usage)
+	value = append(value, usageData...)
+
+	return c.createSerializedDataMessage(nil, messageFlagIgnoreUnreadable, []tlv{
+		{tlvType: tlvTypeExtraSymmetricKey, tlvLength: uint16(len(value)), tlvValue: value},
+	})
+}
+
+// processDataMessageTLVs dispatches each TLV carried by a decrypted data
+// message to its handler; TLV types other than tlvTypeExtraSymmetricKey are
+// skipped. The data message receive path does not call it, so an incoming
+// request reaches the ExtraSymmetricKeyEventHandler only when the decrypted
+// TLVs are passed here.
+func (c *Conversation) processDataMessageTLVs(tlvs []tlv) error {
+	for _, t := range tlvs {
+		switch t.tlvType {
+		case tlvTypeExtraSymmetricKey:
+			if err := c.receivedExtraSymmetricKeyRequest(t.tlvValue); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// receivedExtraSymmetricKeyRequest handles an incoming TLV type 8, deriving
+// the Extra Symmetric Key for the current session and delivering it to the
+// application's ExtraSymmetricKeyEventHandler, if one is installed.
+func (c *Conversation) receivedExtraSymmetricKeyRequest(value []byte) error {
+	rest, usage, ok := extractWord(value)
+	if !ok {
+		return errors.New("otr: corrupt extra symmetric key request")
+	}
+
+	if c.msgState != encrypted {
+		return errors.New("otr: cannot compute the extra symmetric key outside of an encrypted conversation")
+	}
+
+	// The peer derived their copy of the key from their sendingAESKey,
+	// which on this side of the ratchet is our receivingAESKey, not our
+	// sendingAESKey: using ExtraSymmetricKey here would hand the
+	// application a key that doesn't match the one the peer is using.
+	key := extraSymmetricKeyFrom(c.keys.receivingAESKey[:])
+
+	if c.extraSymmetricKeyEventHandler != nil {
+		c.extraSymmetricKeyEventHandler.HandleExtraSymmetricKeyEvent(usage, rest, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package otr3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestExtraSymmetricKeyFromMatchesSpecFormula(t *testing.T) {
+	aesKey := bytes.Repeat([]byte{0x42}, 16)
+	want := sha256.Sum256(append([]byte{0xff}, aesKey...))
+
+	got := extraSymmetricKeyFrom(aesKey)
+	if !bytes.Equal(got, want[:]) {
+		t.Fatalf("extraSymmetricKeyFrom = %x, want %x", got, want[:])
+	}
+}
+
+func TestExtraSymmetricKeyDiffersBetweenSendingAndReceivingDirections(t *testing.T) {
+	sendingAESKey := bytes.Repeat([]byte{0x01}, 16)
+	receivingAESKey := bytes.Repeat([]byte{0x02}, 16)
+
+	ourKey := extraSymmetricKeyFrom(sendingAESKey)
+	theirKeyAsWeSeeIt := extraSymmetricKeyFrom(receivingAESKey)
+
+	if bytes.Equal(ourKey, theirKeyAsWeSeeIt) {
+		t.Fatal("expected the sending and receiving AES keys to produce different extra symmetric keys")
+	}
+}
+
+func TestExtraSymmetricKeyChangesOnRekey(t *testing.T) {
+	beforeRekey := extraSymmetricKeyFrom(bytes.Repeat([]byte{0xaa}, 16))
+	afterRekey := extraSymmetricKeyFrom(bytes.Repeat([]byte{0xbb}, 16))
+
+	if bytes.Equal(beforeRekey, afterRekey) {
+		t.Fatal("expected a rekey that changes the AES key to also change the extra symmetric key")
+	}
+}
@@ -1,16 +1,112 @@
 package otr3
 
-import "time"
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
 
-// How long after sending a packet should we wait to send a heartbeat?
+// How long after sending a packet should we wait to send a heartbeat, when
+// no HeartbeatPolicy is configured.
 const heartbeatInterval = 60 * time.Second
 
+// HeartbeatPolicy controls how otr3 paces the heartbeat messages it
+// piggybacks on outbound plaintext, so a conversation can rekey even when
+// the user doesn't send anything for a while. The zero value reproduces
+// the previous hard-coded behaviour: a flat 60 second interval, no jitter,
+// and no idle suppression.
+type HeartbeatPolicy struct {
+	// Interval is how long to wait, after the last thing we sent, before
+	// a heartbeat becomes due. Zero means heartbeatInterval.
+	Interval time.Duration
+
+	// Jitter is added to, or subtracted from, Interval uniformly at
+	// random each time a heartbeat deadline is computed, so the timing
+	// isn't predictable to an observer watching traffic. It is drawn
+	// from the Conversation's configured randomness source, so tests can
+	// seed it deterministically.
+	Jitter time.Duration
+
+	// MaxIdle suppresses heartbeats once no user message has been sent
+	// for longer than this, since a heartbeat is only useful to
+	// piggyback rekeying on top of a conversation the user actually
+	// wants to have. Zero means heartbeats are never suppressed for
+	// idleness.
+	MaxIdle time.Duration
+
+	// Clock returns the current time. It defaults to time.Now, and
+	// exists so tests can drive the heartbeat without real delays.
+	Clock func() time.Time
+}
+
+func (p *HeartbeatPolicy) interval() time.Duration {
+	if p.Interval > 0 {
+		return p.Interval
+	}
+	return heartbeatInterval
+}
+
+func (p *HeartbeatPolicy) now() time.Time {
+	if p.Clock != nil {
+		return p.Clock()
+	}
+	return time.Now()
+}
+
+// jitter returns a uniformly random duration in [-Jitter, Jitter), drawn
+// from rand.
+func (p *HeartbeatPolicy) jitter(rand io.Reader) time.Duration {
+	if p.Jitter <= 0 {
+		return 0
+	}
+
+	var buf [8]byte
+	if _, err := io.ReadFull(rand, buf[:]); err != nil {
+		return 0
+	}
+
+	span := int64(p.Jitter) * 2
+	offset := int64(binary.BigEndian.Uint64(buf[:])>>1) % span
+	return time.Duration(offset) - p.Jitter
+}
+
 type heartbeatContext struct {
-	lastSent time.Time
+	lastSent     time.Time
+	lastUser     time.Time
+	nextDeadline time.Time
+	policy       HeartbeatPolicy
 }
 
 func (c *Conversation) updateLastSent() {
-	c.heartbeat.lastSent = time.Now()
+	c.heartbeat.lastSent = c.heartbeat.policy.now()
+	c.heartbeat.nextDeadline = c.heartbeat.lastSent.
+		Add(c.heartbeat.policy.interval()).
+		Add(c.heartbeat.policy.jitter(c.rand()))
+}
+
+// noteUserActivity records that the local user actively sent a message, so
+// HeartbeatPolicy.MaxIdle can tell whether the conversation is still worth
+// keeping alive with heartbeats.
+func (c *Conversation) noteUserActivity() {
+	c.heartbeat.lastUser = c.heartbeat.policy.now()
+}
+
+func (c *Conversation) heartbeatSuppressedByIdle(now time.Time) bool {
+	maxIdle := c.heartbeat.policy.MaxIdle
+	if maxIdle <= 0 || c.heartbeat.lastUser.IsZero() {
+		return false
+	}
+	return now.Sub(c.heartbeat.lastUser) > maxIdle
+}
+
+// heartbeatDue reports whether now is past the deadline computed the last
+// time updateLastSent ran. The jittered deadline is fixed once, when
+// lastSent is recorded, rather than re-rolled on every call: re-rolling it
+// here would burn randomness on calls that never send anything and make
+// "due" non-monotonic, since two checks moments apart could disagree
+// purely because the random offset changed, not because time advanced.
+func (c *Conversation) heartbeatDue(now time.Time) bool {
+	return now.After(c.heartbeat.nextDeadline)
 }
 
 func (c *Conversation) maybeHeartbeat(plain, toSend messageWithHeader, err error) ([]byte, messageWithHeader, messageWithHeader, error) {
@@ -22,20 +118,50 @@ func (c *Conversation) maybeHeartbeat(plain, toSend messageWithHeader, err error
 }
 
 func (c *Conversation) potentialHeartbeat(plain []byte) (toSend messageWithHeader, err error) {
-	if plain != nil {
-		now := time.Now()
-		if c.heartbeat.lastSent.Before(now.Add(-heartbeatInterval)) {
-			dataMsg, err := c.genDataMsgWithFlag(nil, messageFlagIgnoreUnreadable)
-			if err != nil {
-				return nil, err
-			}
-			toSend, err = c.wrapMessageHeader(msgTypeData, dataMsg.serialize())
-			if err != nil {
-				return nil, err
-			}
-			c.updateLastSent()
-			messageEventHeartbeatSent(c)
-		}
+	if plain == nil {
+		return nil, nil
 	}
+
+	now := c.heartbeat.policy.now()
+	if !c.heartbeatDue(now) || c.heartbeatSuppressedByIdle(now) {
+		return nil, nil
+	}
+
+	return c.sendHeartbeat()
+}
+
+func (c *Conversation) sendHeartbeat() (toSend messageWithHeader, err error) {
+	dataMsg, err := c.genDataMsgWithFlag(nil, messageFlagIgnoreUnreadable)
+	if err != nil {
+		return nil, err
+	}
+	toSend, err = c.wrapMessageHeader(msgTypeData, dataMsg.serialize())
+	if err != nil {
+		return nil, err
+	}
+	c.updateLastSent()
+	messageEventHeartbeatSent(c)
 	return
 }
+
+// Heartbeat sends a heartbeat message immediately, if one is due under the
+// configured HeartbeatPolicy, without waiting for the next call to Send. It
+// lets an embedder drive heartbeats from an external ticker instead of only
+// as a side effect of sending a user message.
+func (c *Conversation) Heartbeat() ([]ValidMessage, error) {
+	if c.msgState != encrypted {
+		return nil, nil
+	}
+
+	now := c.heartbeat.policy.now()
+	if !c.heartbeatDue(now) || c.heartbeatSuppressedByIdle(now) {
+		return nil, nil
+	}
+
+	toSend, err := c.sendHeartbeat()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fragEncode(toSend), nil
+}
@@ -0,0 +1,35 @@
+package otr3
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeartbeatDueUsesDeadlineFixedByUpdateLastSent(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := &Conversation{}
+	c.heartbeat.policy = HeartbeatPolicy{
+		Interval: time.Minute,
+		Jitter:   30 * time.Second,
+		Clock:    func() time.Time { return now },
+	}
+
+	c.updateLastSent()
+	deadline := c.heartbeat.nextDeadline
+
+	// Checking heartbeatDue repeatedly, without another updateLastSent in
+	// between, must keep comparing against the same deadline: it must not
+	// move just because heartbeatDue was called again.
+	c.heartbeatDue(now)
+	c.heartbeatDue(now.Add(time.Second))
+	if c.heartbeat.nextDeadline != deadline {
+		t.Fatal("heartbeatDue must not recompute the jittered deadline")
+	}
+}
+
+func TestHeartbeatPolicyDefaultsToHeartbeatInterval(t *testing.T) {
+	var policy HeartbeatPolicy
+	if got := policy.interval(); got != heartbeatInterval {
+		t.Fatalf("interval() = %v, want %v", got, heartbeatInterval)
+	}
+}
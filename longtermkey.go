@@ -0,0 +1,99 @@
+package otr3
+
+import "io"
+
+// keyTypeDSA is the wire tag OTRv3 already uses when serializing a DSA
+// long-term key, ahead of the key's own encoding.
+const keyTypeDSA = uint16(0)
+
+// allowNonDSALongTermKeys is a Policies bit that opts in to accepting a
+// peer's non-DSA LongTermKey (see keyTypeEd25519) during the AKE. It is
+// checked only by the side verifying the peer's key, so when both sides
+// use non-DSA identities both need to set it; a peer that hasn't set it
+// keeps rejecting anything but DSA, so the AKE still fails cleanly against
+// a stock peer that has no notion of alternate key types.
+const allowNonDSALongTermKeys = Policies(1 << 20)
+
+// acceptableLongTermKeyType reports whether a LongTermKey of type t may be
+// used in an AKE governed by policies. DSA is always acceptable, since
+// every OTRv3 peer understands it; any other type requires policies to
+// include allowNonDSALongTermKeys.
+func acceptableLongTermKeyType(t uint16, policies Policies) bool {
+	return t == keyTypeDSA || policies.has(allowNonDSALongTermKeys)
+}
+
+// LongTermKey is implemented by any long-term identity key that can take
+// part in the AKE: sign and verify the reveal/signature messages, and
+// serialize itself with a type tag the way OTRv3 already does for DSA.
+// otr3 ships PublicKey/PrivateKey (DSA, keyTypeDSA) and Ed25519Key
+// (keyTypeEd25519). A peer that hasn't opted in to a given Type must never
+// be offered anything but a DSA key, since it won't know how to parse the
+// rest of the AKE.
+type LongTermKey interface {
+	// Serialize returns the key's type tag followed by its wire
+	// encoding.
+	Serialize() []byte
+
+	// Sign produces a signature over digest, using rand for any
+	// randomness the scheme needs.
+	Sign(rand io.Reader, digest []byte) ([]byte, error)
+
+	// Verify checks sig against digest, returning any bytes that
+	// followed the signature and whether it was valid.
+	Verify(digest, sig []byte) (rest []byte, ok bool)
+
+	// Parse consumes this key, including its type tag, from the front
+	// of data, returning the remaining bytes and whether parsing
+	// succeeded.
+	Parse(data []byte) (rest []byte, ok bool)
+
+	// Type returns the wire tag this key serializes itself with.
+	Type() uint16
+}
+
+// Serialize implements LongTermKey for the built-in DSA public key.
+func (pub *PublicKey) Serialize() []byte {
+	return pub.serialize()
+}
+
+// Sign implements LongTermKey for the built-in DSA private key.
+func (priv *PrivateKey) Sign(rand io.Reader, digest []byte) ([]byte, error) {
+	return priv.sign(rand, digest)
+}
+
+// Verify implements LongTermKey for the built-in DSA public key.
+func (pub *PublicKey) Verify(digest, sig []byte) (rest []byte, ok bool) {
+	return pub.verify(digest, sig)
+}
+
+// Parse implements LongTermKey for the built-in DSA public key.
+func (pub *PublicKey) Parse(data []byte) (rest []byte, ok bool) {
+	return pub.parse(data)
+}
+
+// Type implements LongTermKey for the built-in DSA public key.
+func (pub *PublicKey) Type() uint16 {
+	return keyTypeDSA
+}
+
+// parseLongTermKey peeks at the type tag in front of data and parses the
+// matching LongTermKey implementation, so a verifier doesn't need to know
+// in advance which key type the peer used.
+func parseLongTermKey(data []byte) (key LongTermKey, rest []byte, ok bool) {
+	_, tag, ok := extractShort(data)
+	if !ok {
+		return nil, data, false
+	}
+
+	switch tag {
+	case keyTypeDSA:
+		key = &PublicKey{}
+	case keyTypeEd25519:
+		key = &Ed25519Key{}
+	default:
+		return nil, data, false
+	}
+
+	rest, ok = key.Parse(data)
+	return key, rest, ok
+}
@@ -0,0 +1,80 @@
+package otr3
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestEd25519KeySerializeParseRoundtrip(t *testing.T) {
+	key, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key failed: %v", err)
+	}
+
+	parsed := &Ed25519Key{}
+	rest, ok := parsed.Parse(key.Serialize())
+	if !ok {
+		t.Fatal("expected to parse a serialized Ed25519Key")
+	}
+	if len(rest) != 0 {
+		t.Fatalf("expected no trailing bytes after parsing, got %d", len(rest))
+	}
+	if !bytes.Equal(parsed.Public, key.Public) {
+		t.Fatal("parsed public key does not match the original")
+	}
+}
+
+func TestEd25519KeyParseRejectsWrongType(t *testing.T) {
+	pub := &PublicKey{}
+	if _, ok := (&Ed25519Key{}).Parse(pub.Serialize()); ok {
+		t.Fatal("expected an Ed25519Key to reject data tagged with a different key type")
+	}
+}
+
+func TestEd25519KeySignVerify(t *testing.T) {
+	key, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key failed: %v", err)
+	}
+
+	digest := []byte("hello from the AKE")
+	sig, err := key.Sign(rand.Reader, digest)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	pub := &Ed25519Key{Public: key.Public}
+	if _, ok := pub.Verify(digest, sig); !ok {
+		t.Fatal("expected a signature to verify against the matching digest")
+	}
+	if _, ok := pub.Verify([]byte("tampered"), sig); ok {
+		t.Fatal("expected a signature over a different digest to fail verification")
+	}
+}
+
+func TestEd25519KeySignWithoutPrivateKey(t *testing.T) {
+	key, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key failed: %v", err)
+	}
+
+	pub := &Ed25519Key{Public: key.Public}
+	if _, err := pub.Sign(rand.Reader, []byte("anything")); err == nil {
+		t.Fatal("expected Sign to fail without a private key")
+	}
+}
+
+func TestAcceptableLongTermKeyType(t *testing.T) {
+	var noPolicies Policies
+
+	if !acceptableLongTermKeyType(keyTypeDSA, noPolicies) {
+		t.Fatal("a stock DSA key should always be acceptable")
+	}
+	if acceptableLongTermKeyType(keyTypeEd25519, noPolicies) {
+		t.Fatal("an Ed25519 key should be rejected cleanly without the opt-in policy")
+	}
+	if !acceptableLongTermKeyType(keyTypeEd25519, allowNonDSALongTermKeys) {
+		t.Fatal("an Ed25519 key should be accepted once the opt-in policy is set")
+	}
+}
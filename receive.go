@@ -0,0 +1,29 @@
+package otr3
+
+// ReceiveResult behaves like Receive, but additionally reports whether the
+// Conversation is encrypted once the message has been processed, and any
+// SecurityChange the Conversation underwent while processing it. isEncrypted
+// reflects the conversation's state, not how this particular message
+// arrived. Callers that don't want to install a
+// MessageEventHandler can drive OTR by inspecting the return values
+// instead. SendResult, defined alongside Send, is the equivalent entry
+// point for outbound messages.
+//
+// NewKeys and ConversationEnded are detected from the msgState transition
+// Receive causes, the same way SendResult detects them. SMP progress isn't
+// part of SecurityChange yet and continues to be delivered through the
+// existing SMPEventHandler only; see the doc comment on SecurityChange for
+// why.
+func (c *Conversation) ReceiveResult(message ValidMessage) (plain []byte, isEncrypted bool, change SecurityChange, toSend []ValidMessage, err error) {
+	before := c.msgState
+
+	plain, toSend, err = c.Receive(message)
+	if err != nil {
+		return plain, false, NoChange, toSend, err
+	}
+
+	isEncrypted = c.msgState == encrypted
+	change = securityChangeFromMsgStateTransition(before, c.msgState)
+
+	return plain, isEncrypted, change, toSend, nil
+}
@@ -0,0 +1,30 @@
+package otr3
+
+// SecurityChange describes a transition in the security state of a
+// Conversation, as observed by SendResult and ReceiveResult. It lets a
+// caller that would rather poll for state transitions than register a
+// MessageEventHandler react to the conversation becoming encrypted or
+// ending directly from a return value.
+//
+// SMP progress (secret needed, question asked, success, failure) isn't
+// represented here: this chunk of the tree doesn't carry the SMP state
+// machine, so there is nothing to tap to detect those transitions
+// honestly. Callers that need SMP progress still register an
+// SMPEventHandler; once the SMP subsystem is available to this package,
+// the matching SecurityChange values can be added and threaded through
+// SendResult/ReceiveResult the same way NewKeys and ConversationEnded are.
+type SecurityChange int
+
+const (
+	// NoChange happened during this call.
+	NoChange SecurityChange = iota
+
+	// NewKeys indicates that the conversation entered the encrypted state,
+	// for example after completing an AKE. An AKE that completes while the
+	// conversation is already encrypted is reported as NoChange.
+	NewKeys
+
+	// ConversationEnded indicates that the peer ended the encrypted
+	// conversation.
+	ConversationEnded
+)
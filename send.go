@@ -24,6 +24,7 @@ func (c *Conversation) Send(m ValidMessage) ([]ValidMessage, error) {
 		}
 		return []ValidMessage{append([]byte{}, message...)}, nil
 	case encrypted:
+		c.noteUserActivity()
 		result, err := c.createSerializedDataMessage(message, messageFlagNormal, []tlv{})
 		if err != nil {
 			messageEventEncryptionError(c)
@@ -37,6 +38,43 @@ func (c *Conversation) Send(m ValidMessage) ([]ValidMessage, error) {
 	return nil, errors.New("otr: cannot send message in current state")
 }
 
+// SendResult behaves like Send, but additionally reports whether the
+// message went out Encrypted and any SecurityChange the Conversation
+// underwent as a result of sending it. It is built on top of the existing
+// messageEvent* plumbing, so both APIs can be used side by side: callers
+// that don't want to install a MessageEventHandler can drive OTR by
+// inspecting the return values instead. ReceiveResult, defined alongside
+// Receive, is the equivalent entry point for inbound messages: since
+// Send never drives the AKE or SMP state machines itself, change will
+// always be NoChange here in practice, but it is reported the same way
+// for symmetry with ReceiveResult.
+func (c *Conversation) SendResult(m ValidMessage) (toSend []ValidMessage, isEncrypted bool, change SecurityChange, err error) {
+	before := c.msgState
+
+	toSend, err = c.Send(m)
+
+	isEncrypted = c.msgState == encrypted
+	change = securityChangeFromMsgStateTransition(before, c.msgState)
+
+	return
+}
+
+// securityChangeFromMsgStateTransition classifies a msgState transition
+// observed across a single Send or Receive call into a SecurityChange.
+func securityChangeFromMsgStateTransition(before, after msgState) SecurityChange {
+	if before == after {
+		return NoChange
+	}
+	switch after {
+	case finished:
+		return ConversationEnded
+	case encrypted:
+		return NewKeys
+	default:
+		return NoChange
+	}
+}
+
 func (c *Conversation) fragEncode(msg messageWithHeader) []ValidMessage {
 	bytesPerFragment := c.fragmentSize - c.version.minFragmentSize()
 	return c.fragment(c.encode(msg), bytesPerFragment)
@@ -0,0 +1,22 @@
+package otr3
+
+import "testing"
+
+func TestSecurityChangeFromMsgStateTransition(t *testing.T) {
+	cases := []struct {
+		before, after msgState
+		want          SecurityChange
+	}{
+		{plainText, plainText, NoChange},
+		{encrypted, encrypted, NoChange},
+		{plainText, encrypted, NewKeys},
+		{encrypted, finished, ConversationEnded},
+		{finished, finished, NoChange},
+	}
+
+	for _, c := range cases {
+		if got := securityChangeFromMsgStateTransition(c.before, c.after); got != c.want {
+			t.Errorf("securityChangeFromMsgStateTransition(%v, %v) = %v, want %v", c.before, c.after, got, c.want)
+		}
+	}
+}